@@ -0,0 +1,87 @@
+package glel
+
+import (
+	"fmt"
+	"reflect"
+
+	lua "github.com/yuin/gopher-lua"
+)
+
+// EnvSizeLimitError is returned when an evaluation is aborted because the
+// [Env] passed to it exceeded the budget configured via
+// [WithEnvSizeLimit]. It is not raised for memory a script allocates
+// itself once it is running; see that option's doc comment for why.
+type EnvSizeLimitError struct {
+	// Limit is the configured Env size budget, in bytes.
+	Limit uint64
+}
+
+func (err *EnvSizeLimitError) Error() string {
+	return fmt.Sprintf("glel: env size limit of %d bytes exceeded", err.Limit)
+}
+
+// envSize estimates, in bytes, how much memory env will occupy once its
+// values are bridged into a [lua.LState] by setTable/luar.New, the only
+// call sites that inject caller-controlled data into an evaluation.
+// [Expr] is documented as goroutine-safe and the default pool runs many
+// [lua.LState]s concurrently; unlike a process-wide heap sample, this
+// estimate is entirely local to the one evaluation it's computed for, so
+// it can't be perturbed by a GC cycle or an allocation from a concurrent
+// evaluation. It is still only an estimate: it doesn't account for memory
+// a script allocates itself while running (e.g. building tables in a
+// loop), since gopher-lua has no allocator hook to observe that.
+func envSize(env Env) uint64 {
+	var total uint64
+	for key, value := range env {
+		total += uint64(len(key))
+		total += approxValueSize(value)
+	}
+	return total
+}
+
+// approxValueSize estimates the number of bytes value will occupy once
+// bridged into Lua. Strings and byte slices are sized exactly; everything
+// else falls back to its in-memory Go representation via reflection, a
+// reasonable proxy for the table/userdata luar.New builds for it.
+func approxValueSize(value interface{}) uint64 {
+	switch v := value.(type) {
+	case string:
+		return uint64(len(v))
+	case []byte:
+		return uint64(len(v))
+	case lua.LValue:
+		if s, ok := v.(lua.LString); ok {
+			return uint64(len(s))
+		}
+		return uint64(reflect.TypeOf(v).Size())
+	case nil:
+		return 0
+	default:
+		return approxReflectSize(reflect.ValueOf(value))
+	}
+}
+
+// approxReflectSize walks containers one level deep to avoid unbounded
+// recursion on cyclic or deeply nested values; nested containers are
+// charged their shallow (header) size only.
+func approxReflectSize(rv reflect.Value) uint64 {
+	switch rv.Kind() {
+	case reflect.Ptr, reflect.Interface:
+		if rv.IsNil() {
+			return 0
+		}
+		return approxReflectSize(rv.Elem())
+	case reflect.String:
+		return uint64(rv.Len())
+	case reflect.Slice, reflect.Array:
+		n := rv.Len()
+		if n == 0 {
+			return 0
+		}
+		return uint64(n) * uint64(rv.Type().Elem().Size())
+	case reflect.Map:
+		return uint64(rv.Len()) * uint64(rv.Type().Key().Size()+rv.Type().Elem().Size())
+	default:
+		return uint64(rv.Type().Size())
+	}
+}
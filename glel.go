@@ -63,6 +63,17 @@ end
   BASE_ENV[module_name] = protect_module(BASE_ENV[module_name], module_name)
 end)
 
+function __register_module(name, mod, allowed_fns)
+  local exposed = mod
+  if allowed_fns and #allowed_fns > 0 then
+    exposed = {}
+    for _, fn_name in ipairs(allowed_fns) do
+      exposed[fn_name] = mod[fn_name]
+    end
+  end
+  BASE_ENV[name] = protect_module(exposed, name)
+end
+
 if __envfunc then
   __envfunc(BASE_ENV)
 end
@@ -73,15 +84,48 @@ function sandbox_call(f, nenv)
   setfenv(f, env)
   local ok, result = pcall(f)
   if not ok then
-    error(result) 
+    error(result)
   end
   return result
 end
+
+local function pack(...)
+  local n = select('#', ...)
+  local t = {}
+  for i = 1, n do
+    t[i] = select(i, ...)
+  end
+  t.n = n
+  return t
+end
+
+function sandbox_call_all(f, nenv)
+  local env = setmetatable(nenv or {}, {__index = BASE_ENV})
+  env._G = env._G or env
+  setfenv(f, env)
+  -- pcall's own results (including a possible trailing nil return value)
+  -- must be packed with an explicit count: {pcall(f)} combined with the
+  -- # operator is undefined once the table has a trailing nil, which is
+  -- exactly what a final "return x, nil" produces.
+  local packed = pack(pcall(f))
+  if not packed[1] then
+    error(packed[2])
+  end
+  local results = {}
+  for i = 2, packed.n do
+    results[i - 1] = packed[i]
+  end
+  results.n = packed.n - 1
+  return results
+end
 `
 
 type lStatePool interface {
 	Get() *lua.LState
-	Put(*lua.LState)
+	// Put returns lstate to the pool. evalErr is the error (if any)
+	// returned by the evaluation that just ran on lstate, so pools can
+	// decide to recycle an LState that an evaluation left dirty.
+	Put(lstate *lua.LState, evalErr error)
 	Shutdown()
 }
 
@@ -99,26 +143,45 @@ func (pl *nocacheLStatePool) Get() *lua.LState {
 	return pl.lstate
 }
 
-func (pl *nocacheLStatePool) Put(_ *lua.LState) {
+func (pl *nocacheLStatePool) Put(_ *lua.LState, _ error) {
 }
 
 func (pl *nocacheLStatePool) Shutdown() {
 	pl.lstate.Close()
 }
 
+// pooledStateHealth tracks per-LState metadata used to decide whether a
+// returned LState is recycled or discarded, mirroring how connection
+// pools retire unhealthy connections.
+type pooledStateHealth struct {
+	uses int
+}
+
 type syncLStatePool struct {
 	m       sync.Mutex
 	factory func() *lua.LState
 	pool    []*lua.LState
 	limit   chan struct{}
+
+	// healthCheck returns false if a state should be discarded instead
+	// of pooled, given the error (if any) its last evaluation returned.
+	healthCheck func(lstate *lua.LState, evalErr error) bool
+
+	// maxUses is the number of evaluations an LState may serve before it
+	// is discarded, or 0 for no limit. See [WithMaxUsesPerState].
+	maxUses int
+	states  map[*lua.LState]*pooledStateHealth
 }
 
-func newSyncLStatePool(size int, factory func() *lua.LState) lStatePool {
+func newSyncLStatePool(size int, factory func() *lua.LState, healthCheck func(*lua.LState, error) bool, maxUses int) lStatePool {
 	return &syncLStatePool{
-		m:       sync.Mutex{},
-		factory: factory,
-		pool:    make([]*lua.LState, 0, size),
-		limit:   make(chan struct{}, size),
+		m:           sync.Mutex{},
+		factory:     factory,
+		pool:        make([]*lua.LState, 0, size),
+		limit:       make(chan struct{}, size),
+		healthCheck: healthCheck,
+		maxUses:     maxUses,
+		states:      make(map[*lua.LState]*pooledStateHealth),
 	}
 }
 
@@ -128,16 +191,43 @@ func (pl *syncLStatePool) Get() *lua.LState {
 	defer pl.m.Unlock()
 	n := len(pl.pool)
 	if n == 0 {
-		return pl.factory()
+		lstate := pl.factory()
+		if pl.maxUses > 0 {
+			pl.states[lstate] = &pooledStateHealth{}
+		}
+		return lstate
 	}
 	x := pl.pool[n-1]
 	pl.pool = pl.pool[0 : n-1]
 	return x
 }
 
-func (pl *syncLStatePool) Put(lstate *lua.LState) {
+func (pl *syncLStatePool) Put(lstate *lua.LState, evalErr error) {
 	pl.m.Lock()
 	defer pl.m.Unlock()
+
+	healthy := pl.healthCheck == nil || pl.healthCheck(lstate, evalErr)
+	if healthy && pl.maxUses > 0 {
+		st := pl.states[lstate]
+		if st == nil {
+			st = &pooledStateHealth{}
+			pl.states[lstate] = st
+		}
+		st.uses++
+		healthy = st.uses < pl.maxUses
+	}
+
+	if !healthy {
+		delete(pl.states, lstate)
+		lstate.Close()
+		lstate = pl.factory()
+		if pl.maxUses > 0 {
+			pl.states[lstate] = &pooledStateHealth{}
+		}
+	} else {
+		lstate.RemoveContext()
+		lstate.SetTop(0)
+	}
 	pl.pool = append(pl.pool, lstate)
 	<-pl.limit
 }
@@ -175,6 +265,64 @@ type ExprConfig struct {
 	//     	}
 	//
 	EnvFunc func(*lua.LState) int
+
+	// InstructionLimit caps a single evaluation's approximate Lua VM
+	// instruction budget. Zero disables the limit.
+	// See [WithInstructionLimit].
+	InstructionLimit int
+
+	// EnvSizeLimit caps the estimated size, in bytes, of a single
+	// evaluation's [Env]. Zero disables the limit. See
+	// [WithEnvSizeLimit]; it does not bound memory a script allocates
+	// itself while running.
+	EnvSizeLimit uint64
+
+	// Modules is a list of additional sandboxed modules to expose to
+	// evaluated expressions. See [WithModule].
+	Modules []Module
+
+	// DisableIdentifierCheck disables the compile-time check that rejects
+	// expressions referencing identifiers outside of AllowedFunctions,
+	// WithEnv and WithModule. See [WithDisableIdentifierCheck].
+	DisableIdentifierCheck bool
+
+	// envKeys holds the keys of the [Env] passed to [WithEnv], so that
+	// [checkIdentifiers] can treat them as defined without needing to
+	// introspect an arbitrary EnvFunc.
+	envKeys []string
+
+	// dynamicEnvFunc is true when EnvFunc was installed via
+	// [WithEnvFunc] rather than [WithEnv], meaning its set of injected
+	// globals can't be determined statically, so the identifier check
+	// must be skipped.
+	dynamicEnvFunc bool
+
+	// MaxUsesPerState is the number of evaluations a pooled LState may
+	// serve before it is closed and replaced with a fresh one. Zero
+	// means no limit. See [WithMaxUsesPerState].
+	MaxUsesPerState int
+
+	// RecycleOnError discards a pooled LState instead of reusing it
+	// whenever the evaluation that just ran on it returned an error.
+	// See [WithRecycleOnError].
+	RecycleOnError bool
+}
+
+// Module describes a Lua module to preload and expose inside the sandbox.
+// See [WithModule].
+type Module struct {
+	// Name is the module's name, e.g. "json". It becomes both the name
+	// `require`'d internally and the global table name available to
+	// evaluated expressions.
+	Name string
+
+	// Loader is the module's Lua module loader, as registered with
+	// [lua.LState.PreloadModule].
+	Loader lua.LGFunction
+
+	// Allowed is the list of the module's fields that are exposed to
+	// evaluated expressions. An empty list exposes the whole module.
+	Allowed []string
 }
 
 // ExprOption is an option for [Expr].
@@ -209,6 +357,97 @@ func WithAllowedFunctions(lst string) ExprOption {
 func WithEnvFunc(f func(*lua.LState) int) ExprOption {
 	return func(cfg *ExprConfig) {
 		cfg.EnvFunc = f
+		cfg.dynamicEnvFunc = true
+	}
+}
+
+// WithInstructionLimit caps a single evaluation's approximate Lua VM
+// instruction budget, raising an [*InstructionLimitError] once it is
+// exceeded. gopher-lua has no debug-hook API to count VM instructions
+// directly, so the limit is converted into a deadline on
+// [lua.LState.SetContext], which mainLoopWithContext already checks before
+// every single instruction; this gives deterministic, pre-emptive
+// interruption of CPU-bound expressions (e.g. infinite loops) that
+// [Evaler].EvalContext alone cannot reliably provide on its own, since a
+// caller-supplied context's deadline is usually coarser than a single
+// evaluation. The conversion from instruction count to wall-clock time is
+// necessarily an approximation: actual execution speed varies by opcode
+// mix and hardware.
+func WithInstructionLimit(count int) ExprOption {
+	return func(cfg *ExprConfig) {
+		cfg.InstructionLimit = count
+	}
+}
+
+// WithEnvSizeLimit caps the estimated size of a single evaluation's [Env],
+// raising an [*EnvSizeLimitError] before the evaluation ever runs if it is
+// exceeded. The size is estimated by envSize from the Env values
+// themselves (see that function's doc comment), not sampled from process
+// memory: [Expr] is goroutine-safe and the default pool runs many
+// [lua.LState]s concurrently, so a process-wide heap sample would charge
+// one evaluation for another's allocations or a GC cycle that happened to
+// land at the same time.
+//
+// This only bounds the data an expression is handed in; it is not a
+// general memory guard against a runaway expression (e.g. one that
+// builds huge tables or strings in a loop). gopher-lua has no allocator
+// hook and no way to pause and resume a CallByParam mid-execution, so
+// there is no point at which such growth could be observed or capped
+// short of killing the whole evaluation — which [WithInstructionLimit]
+// already does for the CPU-bound side of that same problem, since an
+// unbounded allocation loop is also an unbounded instruction count.
+func WithEnvSizeLimit(bytes uint64) ExprOption {
+	return func(cfg *ExprConfig) {
+		cfg.EnvSizeLimit = bytes
+	}
+}
+
+// WithModule registers a Lua module loader and exposes it inside the
+// sandbox under name, protected by the same metatable that guards the
+// standard library tables. allowed, if given, restricts the exposed
+// surface to those field names, extending the effective allow-list with
+// exactly what the caller asked for; an empty allowed list exposes the
+// whole module. This lets third-party preloaded modules such as
+// cjoudrey/gluahttp or layeh/gopher-json be opted into one expression
+// evaluator without reimplementing sandbox plumbing; see the glel/modules
+// subpackage for ready-made registrations.
+func WithModule(name string, loader lua.LGFunction, allowed ...string) ExprOption {
+	return func(cfg *ExprConfig) {
+		cfg.Modules = append(cfg.Modules, Module{Name: name, Loader: loader, Allowed: allowed})
+	}
+}
+
+// WithDisableIdentifierCheck disables the compile-time check that rejects
+// expressions referencing identifiers outside of AllowedFunctions,
+// WithEnv and WithModule. Use this when an expression's globals are only
+// known dynamically, e.g. supplied per call through [Evaler].Eval's Env
+// argument rather than through WithEnv. The check is already skipped
+// without this option when [WithEnvFunc] is used or the sandbox is
+// disabled.
+func WithDisableIdentifierCheck() ExprOption {
+	return func(cfg *ExprConfig) {
+		cfg.DisableIdentifierCheck = true
+	}
+}
+
+// WithMaxUsesPerState caps the number of evaluations a pooled [lua.LState]
+// may serve before it is closed and replaced with a freshly constructed
+// one, bounding how much garbage or stack drift a single long-lived state
+// can accumulate across millions of evaluations. Zero (the default)
+// disables the limit. Only has an effect with a non-negative [PoolSize].
+func WithMaxUsesPerState(n int) ExprOption {
+	return func(cfg *ExprConfig) {
+		cfg.MaxUsesPerState = n
+	}
+}
+
+// WithRecycleOnError discards a pooled [lua.LState] instead of returning
+// it for reuse whenever the evaluation that just ran on it returned an
+// error, since a Lua error can leave dangling values on the stack or a
+// dirty registry. Only has an effect with a non-negative [PoolSize].
+func WithRecycleOnError(enabled bool) ExprOption {
+	return func(cfg *ExprConfig) {
+		cfg.RecycleOnError = enabled
 	}
 }
 
@@ -223,6 +462,10 @@ func WithEnv(env Env) ExprOption {
 			}
 			return 0
 		}
+		cfg.envKeys = make([]string, 0, len(env))
+		for key := range env {
+			cfg.envKeys = append(cfg.envKeys, key)
+		}
 	}
 }
 
@@ -233,6 +476,13 @@ type Expr interface {
 	// Compiled expression can be cached and goroutine safe.
 	Compile(expr string) (Evaler, error)
 
+	// CompileScript compiles a full Lua chunk, unlike Compile which only
+	// accepts a single expression. This allows `local` variables,
+	// control-flow statements and multiple return values, still
+	// evaluated under the same sandbox. Use [Evaler].EvalAll to collect
+	// every value produced by the chunk's final return statement.
+	CompileScript(src string) (Evaler, error)
+
 	// Close cleanups this object.
 	Close()
 }
@@ -257,21 +507,61 @@ type Evaler interface {
 	// Note that this function has a performance degradetion
 	// compared with [Evaler].EvalBool.
 	EvalContextBool(context.Context, Env) (bool, error)
+
+	// EvalAll evaluates the object with given environments, returning
+	// every value produced by its final return statement. This is
+	// mainly useful for [Expr].CompileScript results, which may return
+	// more than one value; for a single expression it behaves like
+	// Eval but wraps the result in a single-element slice.
+	EvalAll(Env) ([]lua.LValue, error)
 }
 
 type evaler struct {
-	sandbox bool
-	proto   *lua.FunctionProto
-	fn      *lua.LFunction
-	lpool   lStatePool
+	sandbox          bool
+	proto            *lua.FunctionProto
+	fn               *lua.LFunction
+	lpool            lStatePool
+	instructionLimit int
+	envSizeLimit     uint64
+}
+
+// withDeadline arms the per-evaluation instruction-limit deadline (see
+// [WithInstructionLimit]) on lstate when e.instructionLimit is set,
+// returning a cleanup func that must always be deferred, and a wrapErr
+// func that turns a CallByParam error into an [*InstructionLimitError]
+// when it was that deadline, rather than ctx, that aborted the call.
+func (e *evaler) withDeadline(lstate *lua.LState, ctx context.Context) (wrapErr func(error) error, cleanup func()) {
+	if e.instructionLimit <= 0 {
+		return fixError, func() {}
+	}
+	evalCtx, cancel, _ := withInstructionDeadline(ctx, e.instructionLimit)
+	lstate.SetContext(evalCtx)
+	return func(err error) error {
+			if ctx.Err() == nil {
+				if ierr, ok := asInstructionLimitError(evalCtx, e.instructionLimit); ok {
+					return ierr
+				}
+			}
+			return fixError(err)
+		}, func() {
+			lstate.RemoveContext()
+			cancel()
+		}
 }
 
-func (e *evaler) eval(lstate *lua.LState, env Env) (lua.LValue, error) {
+func (e *evaler) eval(lstate *lua.LState, ctx context.Context, env Env) (lua.LValue, error) {
+	if e.envSizeLimit > 0 {
+		if sz := envSize(env); sz > e.envSizeLimit {
+			return nil, &EnvSizeLimitError{Limit: e.envSizeLimit}
+		}
+	}
 	if e.fn == nil {
 		e.fn = lstate.NewFunctionFromProto(e.proto)
 	} else {
 		e.fn.Env = lstate.Env
 	}
+	wrapErr, cleanup := e.withDeadline(lstate, ctx)
+	defer cleanup()
 	if e.sandbox {
 		ltbl := lstate.NewTable()
 		for key, value := range env {
@@ -282,7 +572,7 @@ func (e *evaler) eval(lstate *lua.LState, env Env) (lua.LValue, error) {
 			NRet:    1,
 			Protect: true,
 		}, e.fn, ltbl); err != nil {
-			return nil, fixError(err)
+			return nil, wrapErr(err)
 		}
 	} else {
 		if env != nil {
@@ -296,7 +586,7 @@ func (e *evaler) eval(lstate *lua.LState, env Env) (lua.LValue, error) {
 			NRet:    1,
 			Protect: true,
 		}); err != nil {
-			return nil, fixError(err)
+			return nil, wrapErr(err)
 		}
 	}
 	ret := lstate.Get(-1)
@@ -307,8 +597,9 @@ func (e *evaler) eval(lstate *lua.LState, env Env) (lua.LValue, error) {
 
 func (e *evaler) Eval(env Env) (lua.LValue, error) {
 	lstate := e.lpool.Get()
-	defer e.lpool.Put(lstate)
-	return e.eval(lstate, env)
+	ret, err := e.eval(lstate, context.Background(), env)
+	e.lpool.Put(lstate, err)
+	return ret, err
 }
 
 func (e *evaler) EvalBool(env Env) (bool, error) {
@@ -322,11 +613,10 @@ func (e *evaler) EvalBool(env Env) (bool, error) {
 func (e *evaler) EvalContext(ctx context.Context, env Env) (lua.LValue, error) {
 	lstate := e.lpool.Get()
 	lstate.SetContext(ctx)
-	defer func() {
-		lstate.RemoveContext()
-		e.lpool.Put(lstate)
-	}()
-	return e.eval(lstate, env)
+	ret, err := e.eval(lstate, ctx, env)
+	lstate.RemoveContext()
+	e.lpool.Put(lstate, err)
+	return ret, err
 }
 
 func (e *evaler) EvalContextBool(ctx context.Context, env Env) (bool, error) {
@@ -337,6 +627,92 @@ func (e *evaler) EvalContextBool(ctx context.Context, env Env) (bool, error) {
 	return lua.LVAsBool(lv), err
 }
 
+func (e *evaler) evalAll(lstate *lua.LState, ctx context.Context, env Env) ([]lua.LValue, error) {
+	if e.envSizeLimit > 0 {
+		if sz := envSize(env); sz > e.envSizeLimit {
+			return nil, &EnvSizeLimitError{Limit: e.envSizeLimit}
+		}
+	}
+	if e.fn == nil {
+		e.fn = lstate.NewFunctionFromProto(e.proto)
+	} else {
+		e.fn.Env = lstate.Env
+	}
+	wrapErr, cleanup := e.withDeadline(lstate, ctx)
+	defer cleanup()
+	if e.sandbox {
+		ltbl := lstate.NewTable()
+		for key, value := range env {
+			setTable(lstate, ltbl, key, value)
+		}
+		if err := lstate.CallByParam(lua.P{
+			Fn:      lstate.GetGlobal("sandbox_call_all"),
+			NRet:    1,
+			Protect: true,
+		}, e.fn, ltbl); err != nil {
+			return nil, wrapErr(err)
+		}
+		resultsTbl := lstate.CheckTable(-1)
+		lstate.Pop(1)
+		// resultsTbl.Len() relies on Lua's # operator, which is undefined
+		// once the packed values contain a trailing nil (e.g. a final
+		// "return x, nil"), so the count comes from the explicit "n"
+		// field sandbox_call_all stores instead.
+		n := int(lua.LVAsNumber(resultsTbl.RawGetString("n")))
+		results := make([]lua.LValue, n)
+		for i := range results {
+			results[i] = resultsTbl.RawGetInt(i + 1)
+		}
+		return results, nil
+	}
+
+	if env != nil {
+		ltbl := lstate.Get(lua.GlobalsIndex).(*lua.LTable)
+		for key, value := range env {
+			setTable(lstate, ltbl, key, value)
+		}
+	}
+	top := lstate.GetTop()
+	if err := lstate.CallByParam(lua.P{
+		Fn:      e.fn,
+		NRet:    lua.MultRet,
+		Protect: true,
+	}); err != nil {
+		return nil, wrapErr(err)
+	}
+	n := lstate.GetTop() - top
+	results := make([]lua.LValue, n)
+	for i := 0; i < n; i++ {
+		results[i] = lstate.Get(top + 1 + i)
+	}
+	lstate.SetTop(top)
+	return results, nil
+}
+
+func (e *evaler) EvalAll(env Env) ([]lua.LValue, error) {
+	lstate := e.lpool.Get()
+	ret, err := e.evalAll(lstate, context.Background(), env)
+	e.lpool.Put(lstate, err)
+	return ret, err
+}
+
+// combineHealthChecks folds several pool health checks into one that
+// reports healthy only if every check does. It returns nil, meaning
+// "always healthy", when checks is empty.
+func combineHealthChecks(checks []func(*lua.LState, error) bool) func(*lua.LState, error) bool {
+	if len(checks) == 0 {
+		return nil
+	}
+	return func(lstate *lua.LState, evalErr error) bool {
+		for _, check := range checks {
+			if !check(lstate, evalErr) {
+				return false
+			}
+		}
+		return true
+	}
+}
+
 type expr struct {
 	lpool lStatePool
 	cfg   *ExprConfig
@@ -366,6 +742,11 @@ func New(opts ...ExprOption) Expr {
 			if err != nil {
 				panic(err)
 			}
+			for _, m := range cfg.Modules {
+				if err := registerModule(lstate, m); err != nil {
+					panic(err)
+				}
+			}
 		} else {
 			if cfg.EnvFunc != nil {
 				if err := lstate.CallByParam(lua.P{
@@ -384,7 +765,13 @@ func New(opts ...ExprOption) Expr {
 	if cfg.PoolSize < 0 {
 		lpool = newNocacheLStatePool(factory)
 	} else {
-		lpool = newSyncLStatePool(cfg.PoolSize, factory)
+		var checks []func(*lua.LState, error) bool
+		if cfg.RecycleOnError {
+			checks = append(checks, func(_ *lua.LState, evalErr error) bool {
+				return evalErr == nil
+			})
+		}
+		lpool = newSyncLStatePool(cfg.PoolSize, factory, combineHealthChecks(checks), cfg.MaxUsesPerState)
 	}
 
 	return &expr{
@@ -394,23 +781,76 @@ func New(opts ...ExprOption) Expr {
 }
 
 func (e *expr) Compile(expr string) (Evaler, error) {
-	reader := strings.NewReader("return (" + expr + ")")
+	return e.compile("return (" + expr + ")")
+}
+
+func (e *expr) CompileScript(src string) (Evaler, error) {
+	return e.compile(src)
+}
+
+func (e *expr) compile(src string) (Evaler, error) {
+	reader := strings.NewReader(src)
 	chunk, err := parse.Parse(reader, "<glel>")
 	if err != nil {
 		return nil, fixError(err)
 	}
+	if !e.cfg.DisableIdentifierCheck && !e.cfg.DisableSandbox && !e.cfg.dynamicEnvFunc {
+		if err := checkIdentifiers(chunk, e.cfg); err != nil {
+			return nil, err
+		}
+	}
 	proto, err := lua.Compile(chunk, "<glel>")
 	if err != nil {
 		return nil, fixError(err)
 	}
 	proto.IsVarArg = 0
-	return &evaler{sandbox: !e.cfg.DisableSandbox, proto: proto, lpool: e.lpool}, nil
+	return &evaler{
+		sandbox:          !e.cfg.DisableSandbox,
+		proto:            proto,
+		lpool:            e.lpool,
+		instructionLimit: e.cfg.InstructionLimit,
+		envSizeLimit:     e.cfg.EnvSizeLimit,
+	}, nil
 }
 
 func (e *expr) Close() {
 	e.lpool.Shutdown()
 }
 
+// registerModule preloads m's loader and exposes it inside the sandbox
+// under m.Name by delegating to the __register_module helper defined in
+// sandboxScript, which closes over BASE_ENV and protect_module.
+func registerModule(lstate *lua.LState, m Module) error {
+	lstate.PreloadModule(m.Name, m.Loader)
+	modTable, err := requireModule(lstate, m.Name)
+	if err != nil {
+		return err
+	}
+	allowedTbl := lstate.NewTable()
+	for i, name := range m.Allowed {
+		lstate.RawSetInt(allowedTbl, i+1, lua.LString(name))
+	}
+	return lstate.CallByParam(lua.P{
+		Fn:      lstate.GetGlobal("__register_module"),
+		NRet:    0,
+		Protect: true,
+	}, lua.LString(m.Name), modTable, allowedTbl)
+}
+
+// requireModule runs Lua's require(name) and returns the module's table.
+func requireModule(lstate *lua.LState, name string) (lua.LValue, error) {
+	if err := lstate.CallByParam(lua.P{
+		Fn:      lstate.GetGlobal("require"),
+		NRet:    1,
+		Protect: true,
+	}, lua.LString(name)); err != nil {
+		return nil, err
+	}
+	v := lstate.Get(-1)
+	lstate.Pop(1)
+	return v, nil
+}
+
 func setTable(lstate *lua.LState, t *lua.LTable, key string, value interface{}) {
 	if lv, ok := value.(lua.LValue); ok {
 		lstate.SetTable(t, lua.LString(key), lv)
@@ -18,7 +18,10 @@ func TestGlel(t *testing.T) {
 		WithPoolSize(-1),
 		WithEnv(Env{
 			"hoge": "foo",
-		}))
+		}),
+		// x, y, d and add are only supplied through EvalBool's per-call
+		// Env, which the compile-time identifier check can't see.
+		WithDisableIdentifierCheck())
 	defer expr.Close()
 	evaler, err := expr.Compile(`hoge == "foo" and add(x, y) == 15 and string.rep("ab", 5) == "ababababab" and d.name == "alice" `)
 	if err != nil {
@@ -84,3 +87,287 @@ func TestGlelContext(t *testing.T) {
 	}
 
 }
+
+func TestGlelInstructionLimit(t *testing.T) {
+	expr := New(
+		WithPoolSize(1),
+		WithInstructionLimit(100000),
+	)
+	defer expr.Close()
+	evaler, err := expr.Compile(`(function() while true do end end)()`)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	_, err = evaler.EvalBool(nil)
+
+	if err == nil {
+		t.Errorf("err should be occrred")
+	} else if _, ok := err.(*InstructionLimitError); !ok {
+		t.Errorf("err should be a *InstructionLimitError, but got %T: %s", err, err.Error())
+	}
+
+	// Pool size is 1, so the LState is reused; a fresh, cheap evaluation
+	// should still succeed within its own instruction budget.
+	evaler2, err := expr.Compile(`1 == 1`)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	result, err := evaler2.EvalBool(nil)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	if !result {
+		t.Errorf("result should be true, but got %v", result)
+	}
+}
+
+func TestGlelEnvSizeLimit(t *testing.T) {
+	expr := New(
+		WithPoolSize(1),
+		WithEnvSizeLimit(1024),
+		WithDisableIdentifierCheck(),
+	)
+	defer expr.Close()
+	evaler, err := expr.Compile(`#payload > 0`)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	// payload is well under the limit, so this evaluation should succeed.
+	result, err := evaler.EvalBool(Env{"payload": "small"})
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	if !result {
+		t.Errorf("result should be true, but got %v", result)
+	}
+
+	// A single Env value larger than the configured limit is rejected
+	// before the evaluation ever runs, since gopher-lua has no allocator
+	// hook to bound a script's own memory use once it's running.
+	_, err = evaler.EvalBool(Env{"payload": strings.Repeat("x", 2048)})
+	if err == nil {
+		t.Errorf("err should be occrred")
+	} else if _, ok := err.(*EnvSizeLimitError); !ok {
+		t.Errorf("err should be a *EnvSizeLimitError, but got %T: %s", err, err.Error())
+	}
+}
+
+// TestGlelEnvSizeLimitDoesNotBoundScriptAllocation documents the actual
+// scope of WithEnvSizeLimit: it has no way to observe or cap memory a
+// script allocates itself while running, so a script that builds a large
+// table on its own, independent of anything passed through Env, is not
+// affected by the limit.
+func TestGlelEnvSizeLimitDoesNotBoundScriptAllocation(t *testing.T) {
+	expr := New(
+		WithPoolSize(1),
+		WithEnvSizeLimit(1024),
+		WithDisableIdentifierCheck(),
+	)
+	defer expr.Close()
+
+	evaler, err := expr.CompileScript(`
+		local filler = "xxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxx"
+		local t = {}
+		for i = 1, 2000 do
+			t[i] = i .. filler
+		end
+		return #t
+	`)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	result, err := evaler.Eval(nil)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	if lua.LVAsNumber(result) != 2000 {
+		t.Errorf("expected 2000, but got %v", result)
+	}
+}
+
+func TestGlelModule(t *testing.T) {
+	loader := func(lstate *lua.LState) int {
+		mod := lstate.NewTable()
+		lstate.SetFuncs(mod, map[string]lua.LGFunction{
+			"greet": func(lstate *lua.LState) int {
+				lstate.Push(lua.LString("hi"))
+				return 1
+			},
+			"secret": func(lstate *lua.LState) int {
+				lstate.Push(lua.LString("nope"))
+				return 1
+			},
+		})
+		lstate.Push(mod)
+		return 1
+	}
+	expr := New(
+		WithPoolSize(-1),
+		WithModule("greeter", loader, "greet"),
+	)
+	defer expr.Close()
+	evaler, err := expr.Compile(`greeter.greet() == "hi" and greeter.secret == nil`)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	result, err := evaler.EvalBool(nil)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	if !result {
+		t.Errorf("result should be true, but got %v", result)
+	}
+}
+
+func TestGlelIdentifierCheck(t *testing.T) {
+	expr := New(WithEnv(Env{"hoge": "foo"}))
+	defer expr.Close()
+
+	if _, err := expr.Compile(`hoge == "foo" and string.upper(hoge) == "FOO"`); err != nil {
+		t.Fatal(err.Error())
+	}
+
+	_, err := expr.Compile(`hoge == "foo" and nope() == "bar"`)
+	if err == nil {
+		t.Fatal("err should be occrred")
+	}
+	cerr, ok := err.(*CompileError)
+	if !ok {
+		t.Fatalf("err should be a *CompileError, but got %T: %s", err, err.Error())
+	}
+	if len(cerr.Identifiers) != 1 || cerr.Identifiers[0].Name != "nope" {
+		t.Errorf("CompileError should list 'nope', but got %+v", cerr.Identifiers)
+	}
+}
+
+// TestGlelIdentifierCheckRestrictedModuleMethod guards against a restricted
+// sandbox module's bare name (e.g. "os") short-circuiting the check for one
+// of its members: AllowedFunctions only grants "math.sqrt", so calling
+// os.execute must still be rejected.
+func TestGlelIdentifierCheckRestrictedModuleMethod(t *testing.T) {
+	expr := New(WithAllowedFunctions("math.sqrt"))
+	defer expr.Close()
+
+	if _, err := expr.Compile(`math.sqrt(4) == 2`); err != nil {
+		t.Fatal(err.Error())
+	}
+
+	_, err := expr.Compile(`os.execute("ls")`)
+	if err == nil {
+		t.Fatal("err should be occrred")
+	}
+	cerr, ok := err.(*CompileError)
+	if !ok {
+		t.Fatalf("err should be a *CompileError, but got %T: %s", err, err.Error())
+	}
+	if len(cerr.Identifiers) != 1 || cerr.Identifiers[0].Name != "os.execute" {
+		t.Errorf("CompileError should list 'os.execute', but got %+v", cerr.Identifiers)
+	}
+}
+
+func TestGlelCompileScript(t *testing.T) {
+	expr := New(WithPoolSize(-1), WithDisableIdentifierCheck())
+	defer expr.Close()
+
+	evaler, err := expr.CompileScript(`
+		local sum = x + y
+		local product = x * y
+		return sum, product, sum > product
+	`)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	results, err := evaler.EvalAll(Env{
+		"x": lua.LNumber(3),
+		"y": lua.LNumber(4),
+	})
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	if len(results) != 3 {
+		t.Fatalf("expected 3 return values, but got %d: %v", len(results), results)
+	}
+	if lua.LVAsNumber(results[0]) != 7 {
+		t.Errorf("sum should be 7, but got %v", results[0])
+	}
+	if lua.LVAsNumber(results[1]) != 12 {
+		t.Errorf("product should be 12, but got %v", results[1])
+	}
+	if lua.LVAsBool(results[2]) {
+		t.Errorf("sum > product should be false, but got %v", results[2])
+	}
+
+	// Eval still works on a script-compiled Evaler and returns only the
+	// first value of the final return statement.
+	single, err := evaler.Eval(Env{
+		"x": lua.LNumber(3),
+		"y": lua.LNumber(4),
+	})
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	if lua.LVAsNumber(single) != 7 {
+		t.Errorf("Eval should return the first value (7), but got %v", single)
+	}
+}
+
+// TestGlelEvalAllTrailingNil guards sandbox_call_all against relying on
+// Lua's # operator, which is undefined once the packed pcall results
+// contain a trailing nil, as they do for the common "return result, nil"
+// success pattern.
+func TestGlelEvalAllTrailingNil(t *testing.T) {
+	expr := New(WithPoolSize(-1))
+	defer expr.Close()
+
+	evaler, err := expr.CompileScript(`return 1, 2, nil`)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	results, err := evaler.EvalAll(nil)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	if len(results) != 3 {
+		t.Fatalf("expected 3 return values, but got %d: %v", len(results), results)
+	}
+	if lua.LVAsNumber(results[0]) != 1 || lua.LVAsNumber(results[1]) != 2 || results[2] != lua.LNil {
+		t.Errorf("expected [1 2 nil], but got %v", results)
+	}
+}
+
+func TestGlelPoolRecycle(t *testing.T) {
+	expr := New(
+		WithPoolSize(1),
+		WithMaxUsesPerState(2),
+		WithRecycleOnError(true),
+		WithDisableIdentifierCheck(),
+	)
+	defer expr.Close()
+
+	bad, err := expr.Compile(`nonexistent_global()`)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	if _, err := bad.EvalBool(nil); err == nil {
+		t.Errorf("err should be occrred")
+	}
+
+	// Pool size is 1: the erroring evaluation above must have been
+	// recycled (WithRecycleOnError) rather than corrupting the one
+	// pooled LState for later evaluations.
+	good, err := expr.Compile(`1 == 1`)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	for i := 0; i < 5; i++ {
+		result, err := good.EvalBool(nil)
+		if err != nil {
+			t.Fatal(err.Error())
+		}
+		if !result {
+			t.Errorf("result should be true, but got %v", result)
+		}
+	}
+}
@@ -0,0 +1,280 @@
+package glel
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/yuin/gopher-lua/ast"
+)
+
+// sandboxModuleNames are the standard-library tables that sandboxScript
+// always exposes under BASE_ENV, regardless of AllowedFunctions, so they
+// are always valid bare identifiers when the sandbox is enabled.
+var sandboxModuleNames = strings.Fields("coroutine math os string table")
+
+// UndefinedIdentifier is a single free identifier rejected by the
+// compile-time identifier check performed by [Expr].Compile.
+type UndefinedIdentifier struct {
+	// Name is the identifier as written in the source, e.g. "foo" or
+	// "io.open" for a dotted module access.
+	Name string
+
+	// Line is the 1-based source line the identifier appears on.
+	Line int
+}
+
+// CompileError is returned by [Expr].Compile when an expression
+// references one or more globals that are neither supplied by
+// [WithEnv]/[WithModule] nor present in [ExprConfig.AllowedFunctions].
+// This surfaces the "nil value" failures such expressions would otherwise
+// only hit at evaluation time, as a single structured, fail-fast error.
+type CompileError struct {
+	// Identifiers lists every disallowed identifier found, in source order.
+	Identifiers []UndefinedIdentifier
+}
+
+func (err *CompileError) Error() string {
+	parts := make([]string, len(err.Identifiers))
+	for i, id := range err.Identifiers {
+		parts[i] = fmt.Sprintf("%s (line %d)", id.Name, id.Line)
+	}
+	return fmt.Sprintf("glel: undefined identifier(s): %s", strings.Join(parts, ", "))
+}
+
+// checkIdentifiers walks chunk and reports every free identifier that
+// isn't a local, isn't statically known to be supplied by cfg's
+// WithEnv/WithModule configuration, and isn't in cfg.AllowedFunctions.
+func checkIdentifiers(chunk []ast.Stmt, cfg *ExprConfig) error {
+	allowed := map[string]bool{}
+	for _, name := range strings.Fields(cfg.AllowedFunctions) {
+		allowed[name] = true
+	}
+	for _, name := range sandboxModuleNames {
+		allowed[name] = true
+	}
+	for _, name := range cfg.envKeys {
+		allowed[name] = true
+	}
+	for _, m := range cfg.Modules {
+		allowed[m.Name] = true
+	}
+	allowed["_G"] = true
+
+	restricted := map[string]bool{}
+	for _, name := range sandboxModuleNames {
+		restricted[name] = true
+	}
+
+	w := &identifierWalker{allowed: allowed, restricted: restricted, locals: map[string]bool{}}
+	w.walkBlock(chunk)
+	if len(w.found) == 0 {
+		return nil
+	}
+	return &CompileError{Identifiers: w.found}
+}
+
+type identifierWalker struct {
+	allowed map[string]bool
+	// restricted holds the base identifiers (the sandboxModuleNames) whose
+	// members are individually allow-listed in AllowedFunctions, so a
+	// dotted access against one of them must match the specific
+	// "module.method" entry rather than short-circuiting on the bare
+	// module name the way a WithEnv/WithModule value does.
+	restricted map[string]bool
+	locals     map[string]bool
+	found      []UndefinedIdentifier
+	seen       map[string]bool
+}
+
+func (w *identifierWalker) reject(name string, line int) {
+	if w.seen == nil {
+		w.seen = map[string]bool{}
+	}
+	key := fmt.Sprintf("%d:%s", line, name)
+	if w.seen[key] {
+		return
+	}
+	w.seen[key] = true
+	w.found = append(w.found, UndefinedIdentifier{Name: name, Line: line})
+}
+
+// walkBlock walks a slice of statements that form a single Lua block,
+// restoring any locals declared within it once the block has been walked
+// so that sibling/outer blocks don't see them.
+func (w *identifierWalker) walkBlock(stmts []ast.Stmt) {
+	var added []string
+	declare := func(name string) {
+		if !w.locals[name] {
+			w.locals[name] = true
+			added = append(added, name)
+		}
+	}
+	for _, stmt := range stmts {
+		switch st := stmt.(type) {
+		case *ast.LocalAssignStmt:
+			// `local function f() ... end` also parses to a
+			// LocalAssignStmt (there is no separate
+			// ast.LocalFunctionStmt), with a single Name/Expr pair
+			// whose Expr is the *ast.FunctionExpr itself. Lua scopes
+			// that name to the function's own body for recursion, so
+			// declare it before walking, unlike a plain `local x = x`
+			// where the right-hand side must still see the outer x.
+			if len(st.Names) == 1 && len(st.Exprs) == 1 {
+				if _, ok := st.Exprs[0].(*ast.FunctionExpr); ok {
+					declare(st.Names[0])
+					w.walkExpr(st.Exprs[0])
+					continue
+				}
+			}
+			for _, e := range st.Exprs {
+				w.walkExpr(e)
+			}
+			for _, name := range st.Names {
+				declare(name)
+			}
+		default:
+			w.walkStmt(stmt)
+		}
+	}
+	for _, name := range added {
+		delete(w.locals, name)
+	}
+}
+
+func (w *identifierWalker) walkStmt(stmt ast.Stmt) {
+	switch st := stmt.(type) {
+	case *ast.FuncCallStmt:
+		w.walkExpr(st.Expr)
+	case *ast.AssignStmt:
+		for _, e := range st.Rhs {
+			w.walkExpr(e)
+		}
+		for _, e := range st.Lhs {
+			w.walkExpr(e)
+		}
+	case *ast.DoBlockStmt:
+		w.walkBlock(st.Stmts)
+	case *ast.WhileStmt:
+		w.walkExpr(st.Condition)
+		w.walkBlock(st.Stmts)
+	case *ast.RepeatStmt:
+		w.walkBlock(st.Stmts)
+		w.walkExpr(st.Condition)
+	case *ast.IfStmt:
+		w.walkExpr(st.Condition)
+		w.walkBlock(st.Then)
+		w.walkBlock(st.Else)
+	case *ast.NumberForStmt:
+		w.walkExpr(st.Init)
+		w.walkExpr(st.Limit)
+		if st.Step != nil {
+			w.walkExpr(st.Step)
+		}
+		w.locals[st.Name] = true
+		w.walkBlock(st.Stmts)
+		delete(w.locals, st.Name)
+	case *ast.GenericForStmt:
+		for _, e := range st.Exprs {
+			w.walkExpr(e)
+		}
+		var added []string
+		for _, name := range st.Names {
+			if !w.locals[name] {
+				w.locals[name] = true
+				added = append(added, name)
+			}
+		}
+		w.walkBlock(st.Stmts)
+		for _, name := range added {
+			delete(w.locals, name)
+		}
+	case *ast.FuncDefStmt:
+		w.walkExpr(st.Name.Func)
+		if st.Name.Receiver != nil {
+			w.walkExpr(st.Name.Receiver)
+		}
+		w.walkFunction(st.Func)
+	case *ast.ReturnStmt:
+		for _, e := range st.Exprs {
+			w.walkExpr(e)
+		}
+	}
+}
+
+func (w *identifierWalker) walkFunction(fn *ast.FunctionExpr) {
+	if fn == nil {
+		return
+	}
+	var added []string
+	if fn.ParList != nil {
+		for _, name := range fn.ParList.Names {
+			if !w.locals[name] {
+				w.locals[name] = true
+				added = append(added, name)
+			}
+		}
+	}
+	w.walkBlock(fn.Stmts)
+	for _, name := range added {
+		delete(w.locals, name)
+	}
+}
+
+func (w *identifierWalker) walkExpr(expr ast.Expr) {
+	if expr == nil {
+		return
+	}
+	switch ex := expr.(type) {
+	case *ast.IdentExpr:
+		if !w.locals[ex.Value] && !w.allowed[ex.Value] {
+			w.reject(ex.Value, ex.Line())
+		}
+	case *ast.AttrGetExpr:
+		if base, ok := ex.Object.(*ast.IdentExpr); ok {
+			if key, ok := ex.Key.(*ast.StringExpr); ok && !w.locals[base.Value] {
+				dotted := base.Value + "." + key.Value
+				if w.allowed[dotted] {
+					return
+				}
+				if !w.restricted[base.Value] && w.allowed[base.Value] {
+					return
+				}
+				w.reject(dotted, ex.Line())
+				return
+			}
+		}
+		w.walkExpr(ex.Object)
+		w.walkExpr(ex.Key)
+	case *ast.FuncCallExpr:
+		w.walkExpr(ex.Func)
+		w.walkExpr(ex.Receiver)
+		for _, a := range ex.Args {
+			w.walkExpr(a)
+		}
+	case *ast.ArithmeticOpExpr:
+		w.walkExpr(ex.Lhs)
+		w.walkExpr(ex.Rhs)
+	case *ast.RelationalOpExpr:
+		w.walkExpr(ex.Lhs)
+		w.walkExpr(ex.Rhs)
+	case *ast.LogicalOpExpr:
+		w.walkExpr(ex.Lhs)
+		w.walkExpr(ex.Rhs)
+	case *ast.StringConcatOpExpr:
+		w.walkExpr(ex.Lhs)
+		w.walkExpr(ex.Rhs)
+	case *ast.UnaryMinusOpExpr:
+		w.walkExpr(ex.Expr)
+	case *ast.UnaryNotOpExpr:
+		w.walkExpr(ex.Expr)
+	case *ast.UnaryLenOpExpr:
+		w.walkExpr(ex.Expr)
+	case *ast.TableExpr:
+		for _, f := range ex.Fields {
+			w.walkExpr(f.Key)
+			w.walkExpr(f.Value)
+		}
+	case *ast.FunctionExpr:
+		w.walkFunction(ex)
+	}
+}
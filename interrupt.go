@@ -0,0 +1,62 @@
+package glel
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// instructionBudgetUnit approximates the wall-clock cost of a single Lua
+// VM instruction. gopher-lua has no debug-hook API (no SetHook, no
+// lua.MaskCount, nothing under "hook" in the whole package tree), so there
+// is no way to count VM instructions directly. What it does have is
+// [lua.LState.SetContext]: once set, mainLoopWithContext checks
+// ctx.Done() before executing every single instruction, which is exactly
+// the deterministic, pre-emptive interruption point an instruction-count
+// hook would have given us. [WithInstructionLimit] is therefore enforced
+// by converting its instruction count into a deadline on this scale and
+// relying on that per-instruction context check. This makes *when*
+// interruption fires deterministic (the very next instruction after the
+// deadline), but *how many* instructions actually ran before that only an
+// approximation, since real execution speed varies by opcode mix and
+// hardware.
+const instructionBudgetUnit = 100 * time.Nanosecond
+
+// InstructionLimitError is returned when an evaluation is aborted because
+// it ran past the budget configured via [WithInstructionLimit].
+type InstructionLimitError struct {
+	// Limit is the configured instruction budget.
+	Limit int
+}
+
+func (err *InstructionLimitError) Error() string {
+	return fmt.Sprintf("glel: instruction limit of %d exceeded", err.Limit)
+}
+
+// withInstructionDeadline derives a context from parent that is cancelled
+// once limit's approximate instruction budget (see instructionBudgetUnit)
+// elapses. ok is false when limit is zero, in which case ctx is parent
+// unchanged; cancel must always be called once ctx is no longer needed.
+func withInstructionDeadline(parent context.Context, limit int) (ctx context.Context, cancel context.CancelFunc, ok bool) {
+	if limit <= 0 {
+		return parent, func() {}, false
+	}
+	ctx, cancel = context.WithTimeout(parent, time.Duration(limit)*instructionBudgetUnit)
+	return ctx, cancel, true
+}
+
+// asInstructionLimitError reports whether ctx was cancelled by the
+// deadline withInstructionDeadline installed for limit, returning the
+// typed error to surface to the caller in place of the raw
+// "context deadline exceeded" propagated out of the Lua VM. A deadline
+// ctx inherited from its parent looks the same, so callers check the
+// parent context first.
+func asInstructionLimitError(ctx context.Context, limit int) (*InstructionLimitError, bool) {
+	if limit <= 0 {
+		return nil, false
+	}
+	if ctx.Err() != context.DeadlineExceeded {
+		return nil, false
+	}
+	return &InstructionLimitError{Limit: limit}, true
+}
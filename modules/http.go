@@ -0,0 +1,16 @@
+package modules
+
+import (
+	"net/http"
+
+	"github.com/cjoudrey/gluahttp"
+
+	"github.com/yuin/glel"
+)
+
+// HTTP registers cjoudrey/gluahttp as a read-only "http" module, exposing
+// only get/head so evaluated expressions can fetch data without gaining
+// the ability to mutate remote state through post/put/delete.
+func HTTP() glel.ExprOption {
+	return glel.WithModule("http", gluahttp.NewHttpModule(&http.Client{}).Loader, "get", "head")
+}
@@ -0,0 +1,18 @@
+// Package modules provides ready-made [glel.ExprOption] registrations for
+// commonly used, safe-by-default Lua modules, built on top of
+// [glel.WithModule] so callers can opt into them with one line instead of
+// reimplementing sandbox plumbing.
+package modules
+
+import (
+	json "github.com/layeh/gopher-json"
+
+	"github.com/yuin/glel"
+)
+
+// JSON registers layeh/gopher-json as a "json" module, exposing only
+// encode/decode so evaluated expressions can serialize and parse JSON
+// values without gaining access to the rest of gopher-json's internals.
+func JSON() glel.ExprOption {
+	return glel.WithModule("json", json.Loader, "encode", "decode")
+}